@@ -0,0 +1,304 @@
+package quotes
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+const (
+	sqlChannelAdd       = `INSERT INTO quotes (date, author, quote, channel) VALUES(?, ?, ?, ?);`
+	sqlChannelCount     = `SELECT COUNT(*) FROM quotes WHERE channel = ?;`
+	sqlChannelGet       = `SELECT id, quote FROM quotes WHERE channel = ? ORDER BY RANDOM() LIMIT 1;`
+	sqlChannelGetSeq    = `SELECT id, quote FROM quotes WHERE channel = ? ORDER BY id LIMIT 1 OFFSET ?;`
+	sqlChannelDetailSeq = `SELECT id, date, author FROM quotes WHERE channel = ? ORDER BY id LIMIT 1 OFFSET ?;`
+	sqlChannelGetAll    = `SELECT id, date, author, quote, channel FROM quotes WHERE channel = ? ORDER BY id DESC;`
+	sqlChannelSeqToID   = `SELECT id FROM quotes WHERE channel = ? ORDER BY id LIMIT 1 OFFSET ?;`
+	sqlChannelSeqForID  = `SELECT COUNT(*) FROM quotes WHERE channel = ? AND id <= ?;`
+)
+
+// ScopedQuoteDB is a view over a QuoteDB restricted to a single channel.
+// Quotes within a channel are addressed by a per-channel sequential
+// index (1, 2, 3, ...) rather than the global row id, so separate
+// channels can each present their quotes as "#1", "#2" and so on.
+//
+// Every query here except SearchByTags runs through a *sql.Stmt prepared
+// once in OpenDB, and every method accepts a context via its Ctx
+// variant, since per-channel access is the realistic hot path for a
+// multi-tenant bot.
+type ScopedQuoteDB struct {
+	db      *QuoteDB
+	channel string
+}
+
+// Scope returns a handle restricted to the given channel. An empty
+// channel selects the quotes stored without a channel, which is where the
+// unscoped QuoteDB.AddQuote puts them; like any scope it addresses them by
+// per-channel index, while the unscoped QuoteDB methods use global ids and
+// see every channel.
+func (q *QuoteDB) Scope(channel string) *ScopedQuoteDB {
+	return &ScopedQuoteDB{db: q, channel: channel}
+}
+
+// prepareChannelStatements prepares the channel-filtered *sql.Stmt
+// handles reused by ScopedQuoteDB queries; DelQuote and EditQuote reuse
+// QuoteDB's own delete and edit statements. Called from
+// QuoteDB.prepareStatements as part of OpenDB.
+func (q *QuoteDB) prepareChannelStatements() (err error) {
+	prep := func(query string, dst **sql.Stmt) {
+		if err != nil {
+			return
+		}
+		*dst, err = q.db.Prepare(query)
+	}
+
+	prep(sqlChannelAdd, &q.stmtChannelAdd)
+	prep(sqlChannelCount, &q.stmtChannelCount)
+	prep(sqlChannelGet, &q.stmtChannelGet)
+	prep(sqlChannelGetSeq, &q.stmtChannelGetSeq)
+	prep(sqlChannelDetailSeq, &q.stmtChannelDetailSeq)
+	prep(sqlChannelSeqToID, &q.stmtChannelSeqToID)
+	prep(sqlChannelSeqForID, &q.stmtChannelSeqForID)
+	prep(sqlChannelGetAll, &q.stmtChannelGetAll)
+	prep(sqlRandomQuoteByTagChannel, &q.stmtRandomQuoteByTagChannel)
+	prep(sqlSearchChannel, &q.stmtSearchChannel)
+	prep(sqlSearchCountChannel, &q.stmtSearchCountChannel)
+
+	return err
+}
+
+// seqToID resolves a 1-based per-channel sequential index to its
+// underlying global row id.
+func (s *ScopedQuoteDB) seqToID(ctx context.Context, seq int) (id int, err error) {
+	err = s.db.stmtChannelSeqToID.QueryRowContext(ctx, s.channel, seq-1).Scan(&id)
+	return
+}
+
+// NQuotes returns the number of quotes in this channel.
+func (s *ScopedQuoteDB) NQuotes() (n int, err error) {
+	return s.NQuotesCtx(context.Background())
+}
+
+// NQuotesCtx returns the number of quotes in this channel, aborting
+// early if ctx is canceled or its deadline is exceeded.
+func (s *ScopedQuoteDB) NQuotesCtx(ctx context.Context) (n int, err error) {
+	err = s.db.stmtChannelCount.QueryRowContext(ctx, s.channel).Scan(&n)
+	return
+}
+
+// AddQuote adds a quote to this channel.
+func (s *ScopedQuoteDB) AddQuote(author, quote string) (id int64, err error) {
+	return s.AddQuoteCtx(context.Background(), author, quote)
+}
+
+// AddQuoteCtx adds a quote to this channel, aborting early if ctx is
+// canceled or its deadline is exceeded.
+func (s *ScopedQuoteDB) AddQuoteCtx(ctx context.Context, author, quote string) (id int64, err error) {
+	s.db.Lock()
+	defer s.db.Unlock()
+
+	var res sql.Result
+	res, err = s.db.stmtChannelAdd.ExecContext(ctx, time.Now().Unix(), author, quote, s.channel)
+	if err != nil {
+		return
+	}
+
+	if id, err = res.LastInsertId(); err != nil {
+		id = 0
+	}
+
+	s.db.nQuotes++
+	return
+}
+
+// RandomQuote gets a random existing quote from this channel, returning
+// its per-channel sequential index.
+func (s *ScopedQuoteDB) RandomQuote() (seq int, quote string, err error) {
+	return s.RandomQuoteCtx(context.Background())
+}
+
+// RandomQuoteCtx gets a random existing quote from this channel,
+// returning its per-channel sequential index, aborting early if ctx is
+// canceled or its deadline is exceeded.
+func (s *ScopedQuoteDB) RandomQuoteCtx(ctx context.Context) (seq int, quote string, err error) {
+	var id int
+	if err = s.db.stmtChannelGet.QueryRowContext(ctx, s.channel).Scan(&id, &quote); err != nil {
+		return
+	}
+	err = s.db.stmtChannelSeqForID.QueryRowContext(ctx, s.channel, id).Scan(&seq)
+	return
+}
+
+// GetQuote gets a quote by its per-channel sequential index.
+func (s *ScopedQuoteDB) GetQuote(seq int) (quote string, err error) {
+	return s.GetQuoteCtx(context.Background(), seq)
+}
+
+// GetQuoteCtx gets a quote by its per-channel sequential index, aborting
+// early if ctx is canceled or its deadline is exceeded.
+func (s *ScopedQuoteDB) GetQuoteCtx(ctx context.Context, seq int) (quote string, err error) {
+	var id int
+	err = s.db.stmtChannelGetSeq.QueryRowContext(ctx, s.channel, seq-1).Scan(&id, &quote)
+	return
+}
+
+// GetDetails gets metadata about a quote by its per-channel sequential index.
+func (s *ScopedQuoteDB) GetDetails(seq int) (date int64, author string, err error) {
+	return s.GetDetailsCtx(context.Background(), seq)
+}
+
+// GetDetailsCtx gets metadata about a quote by its per-channel
+// sequential index, aborting early if ctx is canceled or its deadline
+// is exceeded.
+func (s *ScopedQuoteDB) GetDetailsCtx(ctx context.Context, seq int) (date int64, author string, err error) {
+	var id int
+	err = s.db.stmtChannelDetailSeq.QueryRowContext(ctx, s.channel, seq-1).Scan(&id, &date, &author)
+	return
+}
+
+// DelQuote deletes a quote from this channel by its per-channel
+// sequential index.
+func (s *ScopedQuoteDB) DelQuote(seq int) (bool, error) {
+	return s.DelQuoteCtx(context.Background(), seq)
+}
+
+// DelQuoteCtx deletes a quote from this channel by its per-channel
+// sequential index, aborting early if ctx is canceled or its deadline
+// is exceeded.
+func (s *ScopedQuoteDB) DelQuoteCtx(ctx context.Context, seq int) (bool, error) {
+	id, err := s.seqToID(ctx, seq)
+	if err != nil {
+		return false, err
+	}
+
+	res, err := s.db.stmtDel.ExecContext(ctx, id)
+	if err != nil {
+		return false, err
+	}
+	r, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	if r == 1 {
+		s.db.Lock()
+		defer s.db.Unlock()
+		s.db.nQuotes--
+		return true, nil
+	}
+	return false, nil
+}
+
+// EditQuote edits a quote in this channel by its per-channel sequential
+// index.
+func (s *ScopedQuoteDB) EditQuote(seq int, quote string) (bool, error) {
+	return s.EditQuoteCtx(context.Background(), seq, quote)
+}
+
+// EditQuoteCtx edits a quote in this channel by its per-channel
+// sequential index, aborting early if ctx is canceled or its deadline
+// is exceeded.
+func (s *ScopedQuoteDB) EditQuoteCtx(ctx context.Context, seq int, quote string) (bool, error) {
+	id, err := s.seqToID(ctx, seq)
+	if err != nil {
+		return false, err
+	}
+
+	res, err := s.db.stmtEdit.ExecContext(ctx, quote, id)
+	if err != nil {
+		return false, err
+	}
+	r, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return r == 1, nil
+}
+
+// GetAll returns every quote in this channel, newest first.
+func (s *ScopedQuoteDB) GetAll() ([]Quote, error) {
+	return s.GetAllCtx(context.Background())
+}
+
+// GetAllCtx returns every quote in this channel, newest first, aborting
+// early if ctx is canceled or its deadline is exceeded.
+func (s *ScopedQuoteDB) GetAllCtx(ctx context.Context) ([]Quote, error) {
+	rows, err := s.db.stmtChannelGetAll.QueryContext(ctx, s.channel)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	quotes := make([]Quote, 0)
+	for rows.Next() {
+		var date int64
+		q := Quote{}
+		if err = rows.Scan(&q.ID, &date, &q.Author, &q.Quote, &q.Channel); err != nil {
+			return nil, err
+		}
+		q.Date = time.Unix(date, 0).UTC()
+		quotes = append(quotes, q)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return quotes, nil
+}
+
+// RandomQuoteByTag gets a random quote from this channel carrying the
+// given tag, returning its per-channel sequential index.
+func (s *ScopedQuoteDB) RandomQuoteByTag(tag string) (seq int, quote string, err error) {
+	return s.RandomQuoteByTagCtx(context.Background(), tag)
+}
+
+// RandomQuoteByTagCtx gets a random quote from this channel carrying the
+// given tag, returning its per-channel sequential index, aborting early
+// if ctx is canceled or its deadline is exceeded.
+func (s *ScopedQuoteDB) RandomQuoteByTagCtx(ctx context.Context, tag string) (seq int, quote string, err error) {
+	var id int
+	if err = s.db.stmtRandomQuoteByTagChannel.QueryRowContext(ctx, tag, s.channel).Scan(&id, &quote); err != nil {
+		return
+	}
+	err = s.db.stmtChannelSeqForID.QueryRowContext(ctx, s.channel, id).Scan(&seq)
+	return
+}
+
+// SearchByTags returns quotes in this channel matching the tag filter;
+// see QuoteDB.SearchByTags for the any/all semantics.
+func (s *ScopedQuoteDB) SearchByTags(any, all []string) ([]Quote, error) {
+	return s.SearchByTagsCtx(context.Background(), any, all)
+}
+
+// SearchByTagsCtx is SearchByTags with a context. The underlying query
+// is built dynamically based on len(any)/len(all), so unlike the rest
+// of ScopedQuoteDB it isn't backed by a prepared statement.
+func (s *ScopedQuoteDB) SearchByTagsCtx(ctx context.Context, any, all []string) ([]Quote, error) {
+	return s.db.searchByTags(ctx, s.channel, true, any, all)
+}
+
+// SearchQuotes performs a full-text search over this channel's quotes;
+// see QuoteDB.SearchQuotes for the FTS5 MATCH syntax.
+func (s *ScopedQuoteDB) SearchQuotes(query string, limit, offset int) ([]Quote, error) {
+	return s.SearchQuotesCtx(context.Background(), query, limit, offset)
+}
+
+// SearchQuotesCtx performs a full-text search over this channel's
+// quotes, aborting early if ctx is canceled or its deadline is
+// exceeded.
+func (s *ScopedQuoteDB) SearchQuotesCtx(ctx context.Context, query string, limit, offset int) ([]Quote, error) {
+	return s.db.searchChannel(ctx, query, s.channel, limit, offset)
+}
+
+// SearchCount returns the number of quotes in this channel matching an
+// FTS5 query.
+func (s *ScopedQuoteDB) SearchCount(query string) (count int, err error) {
+	return s.SearchCountCtx(context.Background(), query)
+}
+
+// SearchCountCtx returns the number of quotes in this channel matching
+// an FTS5 query, aborting early if ctx is canceled or its deadline is
+// exceeded.
+func (s *ScopedQuoteDB) SearchCountCtx(ctx context.Context, query string) (count int, err error) {
+	return s.db.searchCountChannel(ctx, query, s.channel)
+}
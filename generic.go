@@ -0,0 +1,205 @@
+package quotes
+
+import (
+	"database/sql"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// dialect captures the handful of ways SQL dialects diverge for the
+// queries genericStore needs: placeholder syntax, the random-order
+// function, and the primary key column definition.
+type dialect struct {
+	name          string
+	placeholder   func(n int) string
+	randomFunc    string
+	createTableID string
+	// returningID is true for drivers (e.g. lib/pq) whose sql.Result
+	// doesn't implement LastInsertId, requiring an INSERT ... RETURNING
+	// id query instead.
+	returningID bool
+}
+
+func questionPlaceholder(n int) string { return "?" }
+
+func dollarPlaceholder(n int) string { return fmt.Sprintf("$%d", n) }
+
+var postgresDialect = dialect{
+	name:          "postgres",
+	placeholder:   dollarPlaceholder,
+	randomFunc:    "RANDOM()",
+	createTableID: "id BIGSERIAL PRIMARY KEY",
+	returningID:   true,
+}
+
+var mysqlDialect = dialect{
+	name:          "mysql",
+	placeholder:   questionPlaceholder,
+	randomFunc:    "RAND()",
+	createTableID: "id BIGINT PRIMARY KEY AUTO_INCREMENT",
+}
+
+// genericStore is a database/sql-generic Store implementation for
+// Postgres and MySQL, handling their placeholder and RANDOM()/RAND()
+// differences via dialect.
+//
+// Unlike QuoteDB, genericStore does not implement channel scoping or
+// tags: the quotes table carries a channel column for schema
+// compatibility, but every query here ignores it, so all quotes live in
+// one implicit tenant. See the Store doc comment.
+type genericStore struct {
+	db      *sql.DB
+	dialect dialect
+	nQuotes int
+	sync.RWMutex
+}
+
+// openGenericStore opens dsn with driver and creates the quotes table
+// for the given dialect.
+func openGenericStore(driver, dsn string, d dialect) (*genericStore, error) {
+	db, err := sql.Open(driver, dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	gs := &genericStore{db: db, dialect: d}
+
+	createTable := fmt.Sprintf(
+		`CREATE TABLE IF NOT EXISTS quotes (%s, date BIGINT NOT NULL, author TEXT NOT NULL, quote TEXT NOT NULL, channel TEXT NOT NULL DEFAULT '');`,
+		d.createTableID,
+	)
+	if _, err = gs.db.Exec(createTable); err != nil {
+		defer gs.Close()
+		return nil, err
+	}
+
+	if err = gs.db.QueryRow(`SELECT COUNT(*) FROM quotes;`).Scan(&gs.nQuotes); err != nil {
+		defer gs.Close()
+		return nil, err
+	}
+
+	return gs, nil
+}
+
+// Close the database connection.
+func (g *genericStore) Close() error {
+	return g.db.Close()
+}
+
+// NQuotes returns the number of quotes in the database.
+func (g *genericStore) NQuotes() int {
+	g.RLock()
+	defer g.RUnlock()
+	return g.nQuotes
+}
+
+// AddQuote adds a quote to the database.
+func (g *genericStore) AddQuote(author, quote string) (id int64, err error) {
+	g.Lock()
+	defer g.Unlock()
+
+	args := []interface{}{time.Now().Unix(), author, quote}
+
+	if g.dialect.returningID {
+		query := fmt.Sprintf(
+			`INSERT INTO quotes (date, author, quote) VALUES(%s, %s, %s) RETURNING id;`,
+			g.dialect.placeholder(1), g.dialect.placeholder(2), g.dialect.placeholder(3),
+		)
+		err = g.db.QueryRow(query, args...).Scan(&id)
+	} else {
+		query := fmt.Sprintf(
+			`INSERT INTO quotes (date, author, quote) VALUES(%s, %s, %s);`,
+			g.dialect.placeholder(1), g.dialect.placeholder(2), g.dialect.placeholder(3),
+		)
+		var res sql.Result
+		if res, err = g.db.Exec(query, args...); err == nil {
+			id, err = res.LastInsertId()
+		}
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	g.nQuotes++
+	return id, nil
+}
+
+// RandomQuote gets a random existing quote.
+func (g *genericStore) RandomQuote() (id int, quote string, err error) {
+	query := fmt.Sprintf(`SELECT id, quote FROM quotes ORDER BY %s LIMIT 1;`, g.dialect.randomFunc)
+	err = g.db.QueryRow(query).Scan(&id, &quote)
+	return
+}
+
+// GetQuote gets a specific quote by id.
+func (g *genericStore) GetQuote(id int) (quote string, err error) {
+	query := fmt.Sprintf(`SELECT quote FROM quotes WHERE id = %s;`, g.dialect.placeholder(1))
+	err = g.db.QueryRow(query, id).Scan(&quote)
+	return
+}
+
+// DelQuote deletes a quote by id.
+func (g *genericStore) DelQuote(id int) (bool, error) {
+	query := fmt.Sprintf(`DELETE FROM quotes WHERE id = %s;`, g.dialect.placeholder(1))
+	res, err := g.db.Exec(query, id)
+	if err != nil {
+		return false, err
+	}
+	r, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	if r == 1 {
+		g.Lock()
+		defer g.Unlock()
+		g.nQuotes--
+		return true, nil
+	}
+	return false, nil
+}
+
+// EditQuote edits a quote by id.
+func (g *genericStore) EditQuote(id int, quote string) (bool, error) {
+	query := fmt.Sprintf(
+		`UPDATE quotes SET quote = %s WHERE id = %s;`,
+		g.dialect.placeholder(1), g.dialect.placeholder(2),
+	)
+	res, err := g.db.Exec(query, quote, id)
+	if err != nil {
+		return false, err
+	}
+	r, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return r == 1, nil
+}
+
+// GetAll returns every quote in the database, newest first.
+func (g *genericStore) GetAll() ([]Quote, error) {
+	rows, err := g.db.Query(`SELECT id, date, author, quote FROM quotes ORDER BY id DESC;`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	quotes := make([]Quote, 0)
+	for rows.Next() {
+		var date int64
+		q := Quote{}
+		if err = rows.Scan(&q.ID, &date, &q.Author, &q.Quote); err != nil {
+			return nil, err
+		}
+		q.Date = time.Unix(date, 0).UTC()
+		quotes = append(quotes, q)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return quotes, nil
+}
+
+var _ Store = (*genericStore)(nil)
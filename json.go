@@ -0,0 +1,143 @@
+package quotes
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"io"
+	"time"
+)
+
+const (
+	sqlExportAll = `SELECT id, date, author, quote, channel FROM quotes ORDER BY id;`
+	sqlImportAdd = `INSERT INTO quotes (date, author, quote, channel) VALUES(?, ?, ?, ?);`
+)
+
+// ImportMode controls how ImportJSON reconciles incoming quotes with
+// any that already exist in the database.
+type ImportMode int
+
+const (
+	// ImportAppend inserts every incoming quote under a freshly assigned id.
+	ImportAppend ImportMode = iota
+	// ImportReplace truncates the existing quotes before importing.
+	ImportReplace
+	// ImportMerge skips incoming quotes that duplicate an existing
+	// (author, quote) pair.
+	ImportMerge
+)
+
+// ExportJSON streams every quote as newline-delimited JSON (ndjson), one
+// Quote object per line, so large databases can be dumped without
+// holding the whole result set in memory.
+func (q *QuoteDB) ExportJSON(w io.Writer) error {
+	rows, err := q.db.Query(sqlExportAll)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	enc := json.NewEncoder(w)
+	for rows.Next() {
+		var date int64
+		quote := Quote{}
+		if err = rows.Scan(&quote.ID, &date, &quote.Author, &quote.Quote, &quote.Channel); err != nil {
+			return err
+		}
+		quote.Date = time.Unix(date, 0).UTC()
+		if err = enc.Encode(&quote); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// ImportJSON reads ndjson Quote records from r and inserts them
+// according to mode. The whole import runs in a single transaction with
+// a prepared insert statement for throughput.
+func (q *QuoteDB) ImportJSON(r io.Reader, mode ImportMode) (added, skipped int, err error) {
+	q.Lock()
+	defer q.Unlock()
+
+	tx, err := q.db.Begin()
+	if err != nil {
+		return 0, 0, err
+	}
+	defer tx.Rollback()
+
+	if mode == ImportReplace {
+		if _, err = tx.Exec(`DELETE FROM quotes;`); err != nil {
+			return 0, 0, err
+		}
+	}
+
+	seen := make(map[[32]byte]bool)
+	if mode == ImportMerge {
+		rows, qerr := tx.Query(`SELECT author, quote FROM quotes;`)
+		if qerr != nil {
+			return 0, 0, qerr
+		}
+		for rows.Next() {
+			var author, quote string
+			if err = rows.Scan(&author, &quote); err != nil {
+				rows.Close()
+				return 0, 0, err
+			}
+			seen[dedupeHash(author, quote)] = true
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return 0, 0, err
+		}
+	}
+
+	stmt, err := tx.Prepare(sqlImportAdd)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer stmt.Close()
+
+	dec := json.NewDecoder(r)
+	for {
+		quote := Quote{}
+		if err = dec.Decode(&quote); err != nil {
+			if err == io.EOF {
+				err = nil
+				break
+			}
+			return added, skipped, err
+		}
+
+		if mode == ImportMerge {
+			h := dedupeHash(quote.Author, quote.Quote)
+			if seen[h] {
+				skipped++
+				continue
+			}
+			seen[h] = true
+		}
+
+		if _, err = stmt.Exec(quote.Date.Unix(), quote.Author, quote.Quote, quote.Channel); err != nil {
+			return added, skipped, err
+		}
+		added++
+	}
+
+	if err = tx.Commit(); err != nil {
+		return added, skipped, err
+	}
+
+	if mode == ImportReplace {
+		q.nQuotes = added
+	} else {
+		q.nQuotes += added
+	}
+
+	return added, skipped, nil
+}
+
+// dedupeHash identifies a quote by its author and text for ImportMerge.
+func dedupeHash(author, quote string) [32]byte {
+	return sha256.Sum256([]byte(author + "\x00" + quote))
+}
@@ -0,0 +1,121 @@
+package quotes
+
+import (
+	"database/sql"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// memStore is a Store implementation backed by an in-process slice, with
+// no database file and no temp files. It's intended for tests that need a
+// Store but don't care about persistence. It lives in the same package as
+// QuoteDB, which blank-imports the cgo go-sqlite3 driver, so using it
+// still links that driver.
+//
+// memStore has no concept of channel scoping or tags: Quote.Channel is
+// never set or consulted, so all quotes live in one implicit tenant. See
+// the Store doc comment.
+type memStore struct {
+	sync.RWMutex
+	quotes []Quote
+	nextID int64
+}
+
+// NewMemStore returns an empty in-memory Store.
+func NewMemStore() Store {
+	return &memStore{nextID: 1}
+}
+
+// Close is a no-op; memStore owns no external resources.
+func (m *memStore) Close() error { return nil }
+
+// NQuotes returns the number of quotes in the store.
+func (m *memStore) NQuotes() int {
+	m.RLock()
+	defer m.RUnlock()
+	return len(m.quotes)
+}
+
+// AddQuote adds a quote to the store.
+func (m *memStore) AddQuote(author, quote string) (id int64, err error) {
+	m.Lock()
+	defer m.Unlock()
+
+	id = m.nextID
+	m.nextID++
+	m.quotes = append(m.quotes, Quote{
+		ID:     int(id),
+		Date:   time.Now(),
+		Author: author,
+		Quote:  quote,
+	})
+	return id, nil
+}
+
+// RandomQuote gets a random existing quote.
+func (m *memStore) RandomQuote() (id int, quote string, err error) {
+	m.RLock()
+	defer m.RUnlock()
+
+	if len(m.quotes) == 0 {
+		return 0, "", sql.ErrNoRows
+	}
+	q := m.quotes[rand.Intn(len(m.quotes))]
+	return q.ID, q.Quote, nil
+}
+
+// GetQuote gets a specific quote by id.
+func (m *memStore) GetQuote(id int) (quote string, err error) {
+	m.RLock()
+	defer m.RUnlock()
+
+	for _, q := range m.quotes {
+		if q.ID == id {
+			return q.Quote, nil
+		}
+	}
+	return "", sql.ErrNoRows
+}
+
+// DelQuote deletes a quote by id.
+func (m *memStore) DelQuote(id int) (bool, error) {
+	m.Lock()
+	defer m.Unlock()
+
+	for i, q := range m.quotes {
+		if q.ID == id {
+			m.quotes = append(m.quotes[:i], m.quotes[i+1:]...)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// EditQuote edits a quote by id.
+func (m *memStore) EditQuote(id int, quote string) (bool, error) {
+	m.Lock()
+	defer m.Unlock()
+
+	for i, q := range m.quotes {
+		if q.ID == id {
+			m.quotes[i].Quote = quote
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// GetAll returns every quote in the store, newest first.
+func (m *memStore) GetAll() ([]Quote, error) {
+	m.RLock()
+	defer m.RUnlock()
+
+	quotes := make([]Quote, len(m.quotes))
+	for i, q := range m.quotes {
+		quotes[len(m.quotes)-1-i] = q
+	}
+	return quotes, nil
+}
+
+var _ Store = (*memStore)(nil)
@@ -1,7 +1,9 @@
 package quotes
 
 import (
+	"context"
 	"database/sql"
+	"strings"
 	"sync"
 	"time"
 
@@ -13,16 +15,18 @@ const (
 		`id INTEGER PRIMARY KEY,` +
 		`date INTEGER NOT NULL,` +
 		`author TEXT NOT NULL,` +
-		`quote TEXT NOT NULL);`
-	sqlDateIndex = `CREATE INDEX IF NOT EXISTS quotesdate ON quotes (date);`
-	sqlGetCount  = `SELECT COUNT(*) FROM quotes;`
-	sqlAdd       = `INSERT INTO quotes (date, author, quote) VALUES(?, ?, ?);`
-	sqlDel       = `DELETE FROM quotes WHERE id = ?;`
-	sqlEdit      = `UPDATE quotes SET quote = ? WHERE id = ?;`
-	sqlGet       = `SELECT id, quote FROM quotes ORDER BY RANDOM() LIMIT 1;`
-	sqlGetId     = `SELECT quote FROM quotes WHERE id = ?;`
-	sqlGetDetail = `SELECT date, author FROM quotes WHERE id = ?;`
-	sqlGetAll    = `SELECT id, date, author, quote FROM quotes order by id desc;`
+		`quote TEXT NOT NULL,` +
+		`channel TEXT NOT NULL DEFAULT '');`
+	sqlDateIndex    = `CREATE INDEX IF NOT EXISTS quotesdate ON quotes (date);`
+	sqlChannelIndex = `CREATE INDEX IF NOT EXISTS quoteschannel ON quotes (channel, id);`
+	sqlGetCount     = `SELECT COUNT(*) FROM quotes;`
+	sqlAdd          = `INSERT INTO quotes (date, author, quote) VALUES(?, ?, ?);`
+	sqlDel          = `DELETE FROM quotes WHERE id = ?;`
+	sqlEdit         = `UPDATE quotes SET quote = ? WHERE id = ?;`
+	sqlGet          = `SELECT id, quote FROM quotes ORDER BY RANDOM() LIMIT 1;`
+	sqlGetId        = `SELECT quote FROM quotes WHERE id = ?;`
+	sqlGetDetail    = `SELECT date, author FROM quotes WHERE id = ?;`
+	sqlGetAll       = `SELECT id, date, author, quote, channel FROM quotes order by id desc;`
 )
 
 // QuoteDB provides file storage of quotes via an sqlite database.
@@ -30,19 +34,55 @@ type QuoteDB struct {
 	db      *sql.DB
 	nQuotes int
 	sync.RWMutex
+
+	stmtAdd       *sql.Stmt
+	stmtDel       *sql.Stmt
+	stmtEdit      *sql.Stmt
+	stmtGet       *sql.Stmt
+	stmtGetId     *sql.Stmt
+	stmtGetDetail *sql.Stmt
+	stmtGetAll    *sql.Stmt
+
+	stmtSearch      *sql.Stmt
+	stmtSearchCount *sql.Stmt
+
+	stmtChannelAdd              *sql.Stmt
+	stmtChannelCount            *sql.Stmt
+	stmtChannelGet              *sql.Stmt
+	stmtChannelGetSeq           *sql.Stmt
+	stmtChannelDetailSeq        *sql.Stmt
+	stmtChannelSeqToID          *sql.Stmt
+	stmtChannelSeqForID         *sql.Stmt
+	stmtChannelGetAll           *sql.Stmt
+	stmtRandomQuoteByTagChannel *sql.Stmt
+	stmtSearchChannel           *sql.Stmt
+	stmtSearchCountChannel      *sql.Stmt
 }
 
 // Quote is for serializing to and from the sqlite database.
 type Quote struct {
-	ID     int
-	Date   time.Time
-	Author string
-	Quote  string
+	ID      int
+	Date    time.Time
+	Author  string
+	Quote   string
+	Channel string
+}
+
+// sqliteDSN adds the go-sqlite3 query parameters this package depends
+// on to filename. foreign_keys is per-connection in SQLite, so enabling
+// it via the DSN (rather than a one-shot PRAGMA after sql.Open) is what
+// makes it apply to every connection database/sql's pool may open.
+func sqliteDSN(filename string) string {
+	sep := "?"
+	if strings.Contains(filename, "?") {
+		sep = "&"
+	}
+	return filename + sep + "_foreign_keys=on"
 }
 
 // OpenDB opens the database at the location requested.
 func OpenDB(filename string) (*QuoteDB, error) {
-	db, err := sql.Open("sqlite3", filename)
+	db, err := sql.Open("sqlite3", sqliteDSN(filename))
 	if err != nil {
 		return nil, err
 	}
@@ -53,6 +93,21 @@ func OpenDB(filename string) (*QuoteDB, error) {
 		defer qdb.Close()
 		return nil, err
 	}
+	err = qdb.createTagTables()
+	if err != nil {
+		defer qdb.Close()
+		return nil, err
+	}
+	err = qdb.createFTS()
+	if err != nil {
+		defer qdb.Close()
+		return nil, err
+	}
+	err = qdb.prepareStatements()
+	if err != nil {
+		defer qdb.Close()
+		return nil, err
+	}
 	err = qdb.getCount()
 	if err != nil {
 		defer qdb.Close()
@@ -62,6 +117,35 @@ func OpenDB(filename string) (*QuoteDB, error) {
 	return qdb, nil
 }
 
+// prepareStatements prepares the *sql.Stmt handles reused by the quote,
+// search and channel queries so that hot paths like RandomQuote don't
+// re-parse SQL on every call. The tag, export and import queries are not
+// prepared here.
+func (q *QuoteDB) prepareStatements() (err error) {
+	prep := func(query string, dst **sql.Stmt) {
+		if err != nil {
+			return
+		}
+		*dst, err = q.db.Prepare(query)
+	}
+
+	prep(sqlAdd, &q.stmtAdd)
+	prep(sqlDel, &q.stmtDel)
+	prep(sqlEdit, &q.stmtEdit)
+	prep(sqlGet, &q.stmtGet)
+	prep(sqlGetId, &q.stmtGetId)
+	prep(sqlGetDetail, &q.stmtGetDetail)
+	prep(sqlGetAll, &q.stmtGetAll)
+
+	if err != nil {
+		return err
+	}
+	if err = q.prepareSearchStatements(); err != nil {
+		return err
+	}
+	return q.prepareChannelStatements()
+}
+
 // NQuotes returns the number of quotes in the database.
 func (q *QuoteDB) NQuotes() int {
 	q.RLock()
@@ -75,10 +159,46 @@ func (q *QuoteDB) createTable() (err error) {
 	if err != nil {
 		return
 	}
+	if err = q.addChannelColumn(); err != nil {
+		return
+	}
 	_, err = q.db.Exec(sqlDateIndex)
+	if err != nil {
+		return
+	}
+	_, err = q.db.Exec(sqlChannelIndex)
 	return
 }
 
+// addChannelColumn adds the channel column to databases created before
+// multi-channel support existed.
+func (q *QuoteDB) addChannelColumn() error {
+	rows, err := q.db.Query(`PRAGMA table_info(quotes);`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var cid int
+		var name, ctype string
+		var notNull, pk int
+		var dflt interface{}
+		if err = rows.Scan(&cid, &name, &ctype, &notNull, &dflt, &pk); err != nil {
+			return err
+		}
+		if name == "channel" {
+			return rows.Err()
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return err
+	}
+
+	_, err = q.db.Exec(`ALTER TABLE quotes ADD COLUMN channel TEXT NOT NULL DEFAULT '';`)
+	return err
+}
+
 // getCount refreshes the number of quotes.
 func (q *QuoteDB) getCount() error {
 	return q.db.QueryRow(sqlGetCount).Scan(&q.nQuotes)
@@ -86,6 +206,19 @@ func (q *QuoteDB) getCount() error {
 
 // Close the database file.
 func (q *QuoteDB) Close() error {
+	for _, stmt := range []*sql.Stmt{
+		q.stmtAdd, q.stmtDel, q.stmtEdit, q.stmtGet, q.stmtGetId,
+		q.stmtGetDetail, q.stmtGetAll, q.stmtSearch, q.stmtSearchCount,
+		q.stmtChannelAdd, q.stmtChannelCount, q.stmtChannelGet,
+		q.stmtChannelGetSeq, q.stmtChannelDetailSeq, q.stmtChannelSeqToID,
+		q.stmtChannelSeqForID, q.stmtChannelGetAll, q.stmtRandomQuoteByTagChannel,
+		q.stmtSearchChannel, q.stmtSearchCountChannel,
+	} {
+		if stmt != nil {
+			stmt.Close()
+		}
+	}
+
 	err := q.db.Close()
 	q.db = nil
 	return err
@@ -93,11 +226,17 @@ func (q *QuoteDB) Close() error {
 
 // AddQuote adds a quote to the database.
 func (q *QuoteDB) AddQuote(author, quote string) (id int64, err error) {
+	return q.AddQuoteCtx(context.Background(), author, quote)
+}
+
+// AddQuoteCtx adds a quote to the database, aborting early if ctx is
+// canceled or its deadline is exceeded.
+func (q *QuoteDB) AddQuoteCtx(ctx context.Context, author, quote string) (id int64, err error) {
 	q.Lock()
 	defer q.Unlock()
 
 	var res sql.Result
-	res, err = q.db.Exec(sqlAdd, time.Now().Unix(), author, quote)
+	res, err = q.stmtAdd.ExecContext(ctx, time.Now().Unix(), author, quote)
 	if err != nil {
 		return
 	}
@@ -112,28 +251,46 @@ func (q *QuoteDB) AddQuote(author, quote string) (id int64, err error) {
 
 // RandomQuote gets a random existing quote.
 func (q *QuoteDB) RandomQuote() (id int, quote string, err error) {
-	err = q.db.QueryRow(sqlGet).Scan(&id, &quote)
+	return q.RandomQuoteCtx(context.Background())
+}
+
+// RandomQuoteCtx gets a random existing quote, aborting early if ctx is
+// canceled or its deadline is exceeded.
+func (q *QuoteDB) RandomQuoteCtx(ctx context.Context) (id int, quote string, err error) {
+	err = q.stmtGet.QueryRowContext(ctx).Scan(&id, &quote)
 	return
 }
 
 // GetQuote gets a specific quote by id.
 func (q *QuoteDB) GetQuote(id int) (quote string, err error) {
-	err = q.db.QueryRow(sqlGetId, id).Scan(&quote)
+	return q.GetQuoteCtx(context.Background(), id)
+}
+
+// GetQuoteCtx gets a specific quote by id, aborting early if ctx is
+// canceled or its deadline is exceeded.
+func (q *QuoteDB) GetQuoteCtx(ctx context.Context, id int) (quote string, err error) {
+	err = q.stmtGetId.QueryRowContext(ctx, id).Scan(&quote)
 	return
 }
 
 // GetDetails gets metadata about the quote.
 func (q *QuoteDB) GetDetails(id int) (date int64, author string, err error) {
-	err = q.db.QueryRow(sqlGetDetail, id).Scan(&date, &author)
+	err = q.stmtGetDetail.QueryRow(id).Scan(&date, &author)
 	return
 }
 
 // DelQuote deletes a quote by id.
 func (q *QuoteDB) DelQuote(id int) (bool, error) {
+	return q.DelQuoteCtx(context.Background(), id)
+}
+
+// DelQuoteCtx deletes a quote by id, aborting early if ctx is canceled
+// or its deadline is exceeded.
+func (q *QuoteDB) DelQuoteCtx(ctx context.Context, id int) (bool, error) {
 	var err error
 	var res sql.Result
 	var r int64
-	if res, err = q.db.Exec(sqlDel, id); err != nil {
+	if res, err = q.stmtDel.ExecContext(ctx, id); err != nil {
 		return false, err
 	}
 	if r, err = res.RowsAffected(); err != nil {
@@ -150,10 +307,16 @@ func (q *QuoteDB) DelQuote(id int) (bool, error) {
 
 // EditQuote edits a quote by id.
 func (q *QuoteDB) EditQuote(id int, quote string) (bool, error) {
+	return q.EditQuoteCtx(context.Background(), id, quote)
+}
+
+// EditQuoteCtx edits a quote by id, aborting early if ctx is canceled or
+// its deadline is exceeded.
+func (q *QuoteDB) EditQuoteCtx(ctx context.Context, id int, quote string) (bool, error) {
 	var err error
 	var res sql.Result
 	var r int64
-	if res, err = q.db.Exec(sqlEdit, quote, id); err != nil {
+	if res, err = q.stmtEdit.ExecContext(ctx, quote, id); err != nil {
 		return false, err
 	}
 	if r, err = res.RowsAffected(); err != nil {
@@ -162,10 +325,17 @@ func (q *QuoteDB) EditQuote(id int, quote string) (bool, error) {
 	return r == 1, nil
 }
 
+// GetAll returns every quote in the database, newest first.
 func (qdb *QuoteDB) GetAll() ([]Quote, error) {
+	return qdb.GetAllCtx(context.Background())
+}
+
+// GetAllCtx returns every quote in the database, newest first, aborting
+// early if ctx is canceled or its deadline is exceeded.
+func (qdb *QuoteDB) GetAllCtx(ctx context.Context) ([]Quote, error) {
 	var err error
 
-	rows, err := qdb.db.Query(sqlGetAll)
+	rows, err := qdb.stmtGetAll.QueryContext(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -175,7 +345,7 @@ func (qdb *QuoteDB) GetAll() ([]Quote, error) {
 	q := Quote{}
 	for rows.Next() {
 		var date int64
-		if err = rows.Scan(&q.ID, &date, &q.Author, &q.Quote); err != nil {
+		if err = rows.Scan(&q.ID, &date, &q.Author, &q.Quote, &q.Channel); err != nil {
 			return nil, err
 		}
 
@@ -0,0 +1,172 @@
+package quotes
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func openTestDB(t *testing.T) *QuoteDB {
+	t.Helper()
+	db, err := OpenDB(":memory:")
+	if err != nil {
+		t.Fatalf("OpenDB: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestSearchQuotesFindsIndexedQuotes(t *testing.T) {
+	db := openTestDB(t)
+
+	if _, err := db.AddQuote("alice", "the quick brown fox"); err != nil {
+		t.Fatalf("AddQuote: %v", err)
+	}
+	if _, err := db.AddQuote("bob", "a slow red fox"); err != nil {
+		t.Fatalf("AddQuote: %v", err)
+	}
+
+	quotes, err := db.SearchQuotes("brown", 10, 0)
+	if err != nil {
+		t.Fatalf("SearchQuotes: %v", err)
+	}
+	if len(quotes) != 1 || quotes[0].Author != "alice" {
+		t.Fatalf("SearchQuotes(brown) = %+v, want alice's quote", quotes)
+	}
+
+	quotes, err = db.SearchQuotes("fox", 10, 0)
+	if err != nil {
+		t.Fatalf("SearchQuotes: %v", err)
+	}
+	if len(quotes) != 2 {
+		t.Fatalf("SearchQuotes(fox) = %d quotes, want 2", len(quotes))
+	}
+}
+
+func TestSearchQuotesStaysInSyncAfterEditAndDelete(t *testing.T) {
+	db := openTestDB(t)
+
+	id, err := db.AddQuote("alice", "the quick brown fox")
+	if err != nil {
+		t.Fatalf("AddQuote: %v", err)
+	}
+
+	if ok, err := db.EditQuote(int(id), "the quick blue fox"); err != nil || !ok {
+		t.Fatalf("EditQuote: ok=%v err=%v", ok, err)
+	}
+
+	if quotes, err := db.SearchQuotes("brown", 10, 0); err != nil || len(quotes) != 0 {
+		t.Fatalf("SearchQuotes(brown) after edit = %+v, err=%v, want none", quotes, err)
+	}
+	if quotes, err := db.SearchQuotes("blue", 10, 0); err != nil || len(quotes) != 1 {
+		t.Fatalf("SearchQuotes(blue) after edit = %+v, err=%v, want 1", quotes, err)
+	}
+
+	if ok, err := db.DelQuote(int(id)); err != nil || !ok {
+		t.Fatalf("DelQuote: ok=%v err=%v", ok, err)
+	}
+	if quotes, err := db.SearchQuotes("blue", 10, 0); err != nil || len(quotes) != 0 {
+		t.Fatalf("SearchQuotes(blue) after delete = %+v, err=%v, want none", quotes, err)
+	}
+}
+
+func TestDelQuoteCascadesTags(t *testing.T) {
+	db := openTestDB(t)
+
+	id, err := db.AddQuote("alice", "the quick brown fox")
+	if err != nil {
+		t.Fatalf("AddQuote: %v", err)
+	}
+	if err := db.TagQuote(int(id), "animals", "nature"); err != nil {
+		t.Fatalf("TagQuote: %v", err)
+	}
+
+	if ok, err := db.DelQuote(int(id)); err != nil || !ok {
+		t.Fatalf("DelQuote: ok=%v err=%v", ok, err)
+	}
+
+	var count int
+	if err := db.db.QueryRow(`SELECT COUNT(*) FROM quote_tags WHERE quote_id = ?;`, id).Scan(&count); err != nil {
+		t.Fatalf("query quote_tags: %v", err)
+	}
+	if count != 0 {
+		t.Fatalf("quote_tags rows for deleted quote = %d, want 0 (foreign_keys cascade not applied)", count)
+	}
+}
+
+func TestImportJSONMergeDedupesByAuthorAndQuote(t *testing.T) {
+	db := openTestDB(t)
+
+	if _, err := db.AddQuote("alice", "the quick brown fox"); err != nil {
+		t.Fatalf("AddQuote: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := db.ExportJSON(&buf); err != nil {
+		t.Fatalf("ExportJSON: %v", err)
+	}
+	buf.WriteString(`{"Author":"bob","Quote":"a slow red fox"}` + "\n")
+
+	added, skipped, err := db.ImportJSON(strings.NewReader(buf.String()), ImportMerge)
+	if err != nil {
+		t.Fatalf("ImportJSON: %v", err)
+	}
+	if added != 1 || skipped != 1 {
+		t.Fatalf("ImportJSON(merge) = added=%d skipped=%d, want added=1 skipped=1", added, skipped)
+	}
+	if n := db.NQuotes(); n != 2 {
+		t.Fatalf("NQuotes after merge = %d, want 2", n)
+	}
+}
+
+func TestScopedQuoteDBSeqIndexing(t *testing.T) {
+	db := openTestDB(t)
+
+	if _, err := db.AddQuote("alice", "unscoped quote"); err != nil {
+		t.Fatalf("AddQuote: %v", err)
+	}
+
+	chanA := db.Scope("#a")
+	chanB := db.Scope("#b")
+
+	if _, err := chanA.AddQuote("alice", "a1"); err != nil {
+		t.Fatalf("AddQuote: %v", err)
+	}
+	if _, err := chanA.AddQuote("bob", "a2"); err != nil {
+		t.Fatalf("AddQuote: %v", err)
+	}
+	if _, err := chanB.AddQuote("carol", "b1"); err != nil {
+		t.Fatalf("AddQuote: %v", err)
+	}
+
+	n, err := chanA.NQuotes()
+	if err != nil || n != 2 {
+		t.Fatalf("chanA.NQuotes() = %d, err=%v, want 2", n, err)
+	}
+
+	quote, err := chanA.GetQuote(1)
+	if err != nil || quote != "a1" {
+		t.Fatalf("chanA.GetQuote(1) = %q, err=%v, want a1", quote, err)
+	}
+	quote, err = chanA.GetQuote(2)
+	if err != nil || quote != "a2" {
+		t.Fatalf("chanA.GetQuote(2) = %q, err=%v, want a2", quote, err)
+	}
+
+	quote, err = chanB.GetQuote(1)
+	if err != nil || quote != "b1" {
+		t.Fatalf("chanB.GetQuote(1) = %q, err=%v, want b1", quote, err)
+	}
+
+	if ok, err := chanA.DelQuote(1); err != nil || !ok {
+		t.Fatalf("chanA.DelQuote(1): ok=%v err=%v", ok, err)
+	}
+	quote, err = chanA.GetQuote(1)
+	if err != nil || quote != "a2" {
+		t.Fatalf("chanA.GetQuote(1) after delete = %q, err=%v, want a2 (a1 re-seqs down)", quote, err)
+	}
+
+	if n, err := chanB.NQuotes(); err != nil || n != 1 {
+		t.Fatalf("chanB.NQuotes() after deleting from chanA = %d, err=%v, want 1 (channels stay isolated)", n, err)
+	}
+}
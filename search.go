@@ -0,0 +1,136 @@
+package quotes
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+const (
+	sqlCreateFTS = `CREATE VIRTUAL TABLE IF NOT EXISTS quotes_fts USING fts5(` +
+		`quote, author, content='quotes', content_rowid='id');`
+	sqlFTSInsertTrigger = `CREATE TRIGGER IF NOT EXISTS quotes_ai AFTER INSERT ON quotes BEGIN` +
+		` INSERT INTO quotes_fts(rowid, quote, author) VALUES (new.id, new.quote, new.author);` +
+		` END;`
+	sqlFTSDeleteTrigger = `CREATE TRIGGER IF NOT EXISTS quotes_ad AFTER DELETE ON quotes BEGIN` +
+		` INSERT INTO quotes_fts(quotes_fts, rowid, quote, author) VALUES('delete', old.id, old.quote, old.author);` +
+		` END;`
+	sqlFTSUpdateTrigger = `CREATE TRIGGER IF NOT EXISTS quotes_au AFTER UPDATE ON quotes BEGIN` +
+		` INSERT INTO quotes_fts(quotes_fts, rowid, quote, author) VALUES('delete', old.id, old.quote, old.author);` +
+		` INSERT INTO quotes_fts(rowid, quote, author) VALUES (new.id, new.quote, new.author);` +
+		` END;`
+	sqlFTSCount    = `SELECT COUNT(*) FROM quotes_fts;`
+	sqlFTSBackfill = `INSERT INTO quotes_fts(rowid, quote, author) SELECT id, quote, author FROM quotes;`
+	sqlSearch      = `SELECT q.id, q.date, q.author, q.quote, q.channel FROM quotes q ` +
+		`JOIN quotes_fts ON quotes_fts.rowid = q.id ` +
+		`WHERE quotes_fts MATCH ? ORDER BY bm25(quotes_fts) LIMIT ? OFFSET ?;`
+	sqlSearchCount   = `SELECT COUNT(*) FROM quotes_fts WHERE quotes_fts MATCH ?;`
+	sqlSearchChannel = `SELECT q.id, q.date, q.author, q.quote, q.channel FROM quotes q ` +
+		`JOIN quotes_fts ON quotes_fts.rowid = q.id ` +
+		`WHERE quotes_fts MATCH ? AND q.channel = ? ORDER BY bm25(quotes_fts) LIMIT ? OFFSET ?;`
+	sqlSearchCountChannel = `SELECT COUNT(*) FROM quotes q ` +
+		`JOIN quotes_fts ON quotes_fts.rowid = q.id ` +
+		`WHERE quotes_fts MATCH ? AND q.channel = ?;`
+)
+
+// createFTS creates the quotes_fts virtual table and its sync triggers,
+// backfilling it from the existing quotes table the first time it is
+// created.
+func (q *QuoteDB) createFTS() error {
+	var existed int
+	_ = q.db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type='table' AND name='quotes_fts';`).Scan(&existed)
+
+	if _, err := q.db.Exec(sqlCreateFTS); err != nil {
+		return err
+	}
+	if _, err := q.db.Exec(sqlFTSInsertTrigger); err != nil {
+		return err
+	}
+	if _, err := q.db.Exec(sqlFTSDeleteTrigger); err != nil {
+		return err
+	}
+	if _, err := q.db.Exec(sqlFTSUpdateTrigger); err != nil {
+		return err
+	}
+
+	if existed == 0 {
+		if _, err := q.db.Exec(sqlFTSBackfill); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// prepareSearchStatements prepares the search query handles. Called from
+// QuoteDB.prepareStatements as part of OpenDB.
+func (q *QuoteDB) prepareSearchStatements() (err error) {
+	if q.stmtSearch, err = q.db.Prepare(sqlSearch); err != nil {
+		return err
+	}
+	q.stmtSearchCount, err = q.db.Prepare(sqlSearchCount)
+	return err
+}
+
+// SearchQuotes performs a full-text search over quotes using FTS5 MATCH
+// syntax (phrase, prefix, AND/OR/NOT) and returns results ordered by
+// bm25 relevance.
+func (q *QuoteDB) SearchQuotes(query string, limit, offset int) ([]Quote, error) {
+	return q.SearchQuotesCtx(context.Background(), query, limit, offset)
+}
+
+// SearchQuotesCtx performs a full-text search over quotes, aborting
+// early if ctx is canceled or its deadline is exceeded.
+func (q *QuoteDB) SearchQuotesCtx(ctx context.Context, query string, limit, offset int) ([]Quote, error) {
+	rows, err := q.stmtSearch.QueryContext(ctx, query, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	return scanSearchRows(rows)
+}
+
+// SearchCount returns the number of quotes matching an FTS5 query.
+func (q *QuoteDB) SearchCount(query string) (count int, err error) {
+	err = q.stmtSearchCount.QueryRow(query).Scan(&count)
+	return
+}
+
+// searchChannel is the channel-filtered counterpart to SearchQuotes,
+// used by ScopedQuoteDB.SearchQuotes so a channel-scoped caller only
+// searches its own quotes.
+func (q *QuoteDB) searchChannel(ctx context.Context, query, channel string, limit, offset int) ([]Quote, error) {
+	rows, err := q.stmtSearchChannel.QueryContext(ctx, query, channel, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	return scanSearchRows(rows)
+}
+
+// searchCountChannel is the channel-filtered counterpart to SearchCount.
+func (q *QuoteDB) searchCountChannel(ctx context.Context, query, channel string) (count int, err error) {
+	err = q.stmtSearchCountChannel.QueryRowContext(ctx, query, channel).Scan(&count)
+	return
+}
+
+// scanSearchRows reads the common id/date/author/quote/channel shape
+// shared by search and channel-scoped search queries.
+func scanSearchRows(rows *sql.Rows) ([]Quote, error) {
+	defer rows.Close()
+
+	quotes := make([]Quote, 0)
+	for rows.Next() {
+		var date int64
+		quote := Quote{}
+		if err := rows.Scan(&quote.ID, &date, &quote.Author, &quote.Quote, &quote.Channel); err != nil {
+			return nil, err
+		}
+		quote.Date = time.Unix(date, 0).UTC()
+		quotes = append(quotes, quote)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return quotes, nil
+}
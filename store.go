@@ -0,0 +1,53 @@
+package quotes
+
+import (
+	"fmt"
+)
+
+// Store is the behavior QuoteDB exposes, extracted so that callers can
+// depend on an interface instead of a concrete sqlite-backed type. It is
+// implemented by QuoteDB (sqlite, via OpenDB), genericStore (Postgres or
+// MySQL, via OpenStore), and memStore (in-memory, for tests).
+//
+// Store itself has no notion of channel/tenant scoping or tags — those
+// are QuoteDB-specific extensions (Scope, TagQuote, and friends). Only
+// the sqlite-backed QuoteDB supports them today; genericStore and
+// memStore store every quote under a single implicit tenant, so an
+// OpenStore("postgres", ...) or OpenStore("mysql", ...) caller that
+// depends on per-channel isolation will not get it.
+type Store interface {
+	AddQuote(author, quote string) (id int64, err error)
+	GetQuote(id int) (quote string, err error)
+	RandomQuote() (id int, quote string, err error)
+	DelQuote(id int) (bool, error)
+	EditQuote(id int, quote string) (bool, error)
+	GetAll() ([]Quote, error)
+	NQuotes() int
+	Close() error
+}
+
+// OpenStore opens a Store backed by the named database/sql driver. The
+// "sqlite3" driver returns a *QuoteDB via OpenDB; "postgres" and "mysql"
+// return a dialect-aware generic implementation so operators who already
+// run Postgres or MySQL don't need a separate sqlite file.
+//
+// OpenStore only selects the dialect; it does not register a
+// database/sql driver. Callers asking for "postgres" or "mysql" must
+// blank-import the matching driver package themselves (e.g.
+// `_ "github.com/lib/pq"` or `_ "github.com/go-sql-driver/mysql"`) the
+// same way this package blank-imports mattn/go-sqlite3 for "sqlite3".
+// Without that import, sql.Open fails with "unknown driver".
+func OpenStore(driver, dsn string) (Store, error) {
+	switch driver {
+	case "sqlite3":
+		return OpenDB(dsn)
+	case "postgres":
+		return openGenericStore(driver, dsn, postgresDialect)
+	case "mysql":
+		return openGenericStore(driver, dsn, mysqlDialect)
+	default:
+		return nil, fmt.Errorf("quotes: unsupported driver %q", driver)
+	}
+}
+
+var _ Store = (*QuoteDB)(nil)
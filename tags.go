@@ -0,0 +1,230 @@
+package quotes
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	sqlCreateTagsTable = `CREATE TABLE IF NOT EXISTS tags (` +
+		`id INTEGER PRIMARY KEY,` +
+		`name TEXT UNIQUE COLLATE NOCASE);`
+	sqlCreateQuoteTagsTable = `CREATE TABLE IF NOT EXISTS quote_tags (` +
+		`quote_id INTEGER NOT NULL,` +
+		`tag_id INTEGER NOT NULL,` +
+		`PRIMARY KEY(quote_id, tag_id),` +
+		`FOREIGN KEY(quote_id) REFERENCES quotes(id) ON DELETE CASCADE,` +
+		`FOREIGN KEY(tag_id) REFERENCES tags(id) ON DELETE CASCADE);`
+	sqlTagInsert      = `INSERT OR IGNORE INTO tags (name) VALUES (?);`
+	sqlTagID          = `SELECT id FROM tags WHERE name = ? COLLATE NOCASE;`
+	sqlQuoteTagInsert = `INSERT OR IGNORE INTO quote_tags (quote_id, tag_id) VALUES (?, ?);`
+	sqlQuoteTagDelete = `DELETE FROM quote_tags WHERE quote_id = ? AND tag_id = ?;`
+	sqlQuoteTags      = `SELECT tags.name FROM tags ` +
+		`JOIN quote_tags ON quote_tags.tag_id = tags.id ` +
+		`WHERE quote_tags.quote_id = ? ORDER BY tags.name;`
+	sqlListTags = `SELECT tags.name, COUNT(quote_tags.quote_id) FROM tags ` +
+		`LEFT JOIN quote_tags ON quote_tags.tag_id = tags.id ` +
+		`GROUP BY tags.name ORDER BY tags.name;`
+	sqlRandomQuoteByTag = `SELECT quotes.id, quotes.quote FROM quotes ` +
+		`JOIN quote_tags ON quote_tags.quote_id = quotes.id ` +
+		`JOIN tags ON tags.id = quote_tags.tag_id ` +
+		`WHERE tags.name = ? COLLATE NOCASE ORDER BY RANDOM() LIMIT 1;`
+	sqlRandomQuoteByTagChannel = `SELECT quotes.id, quotes.quote FROM quotes ` +
+		`JOIN quote_tags ON quote_tags.quote_id = quotes.id ` +
+		`JOIN tags ON tags.id = quote_tags.tag_id ` +
+		`WHERE tags.name = ? COLLATE NOCASE AND quotes.channel = ? ORDER BY RANDOM() LIMIT 1;`
+)
+
+// TagCount is a tag and the number of quotes carrying it.
+type TagCount struct {
+	Tag   string
+	Count int
+}
+
+// createTagTables creates the tags and quote_tags tables. Cascading
+// deletes from quotes into quote_tags depend on foreign_keys being on,
+// which OpenDB enables per-connection via the sqlite3 DSN rather than a
+// one-shot PRAGMA here, since database/sql may hand callers a different
+// underlying connection than the one a PRAGMA Exec would land on.
+func (q *QuoteDB) createTagTables() error {
+	if _, err := q.db.Exec(sqlCreateTagsTable); err != nil {
+		return err
+	}
+	_, err := q.db.Exec(sqlCreateQuoteTagsTable)
+	return err
+}
+
+// tagID returns the id of tag, creating it if it doesn't already exist.
+func (q *QuoteDB) tagID(tx *sql.Tx, tag string) (id int64, err error) {
+	if _, err = tx.Exec(sqlTagInsert, tag); err != nil {
+		return 0, err
+	}
+	err = tx.QueryRow(sqlTagID, tag).Scan(&id)
+	return
+}
+
+// TagQuote adds one or more tags to a quote, creating any tag that
+// doesn't already exist.
+func (q *QuoteDB) TagQuote(id int, tags ...string) error {
+	tx, err := q.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, tag := range tags {
+		tagID, err := q.tagID(tx, strings.ToLower(tag))
+		if err != nil {
+			return err
+		}
+		if _, err = tx.Exec(sqlQuoteTagInsert, id, tagID); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// UntagQuote removes one or more tags from a quote. Tags that were
+// never applied to the quote are ignored.
+func (q *QuoteDB) UntagQuote(id int, tags ...string) error {
+	tx, err := q.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, tag := range tags {
+		var tagID int64
+		err := tx.QueryRow(sqlTagID, tag).Scan(&tagID)
+		if err == sql.ErrNoRows {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if _, err = tx.Exec(sqlQuoteTagDelete, id, tagID); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// QuoteTags returns the tags applied to a quote.
+func (q *QuoteDB) QuoteTags(id int) ([]string, error) {
+	rows, err := q.db.Query(sqlQuoteTags, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tags := make([]string, 0)
+	for rows.Next() {
+		var tag string
+		if err = rows.Scan(&tag); err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+
+	return tags, rows.Err()
+}
+
+// ListTags returns every known tag and how many quotes carry it.
+func (q *QuoteDB) ListTags() ([]TagCount, error) {
+	rows, err := q.db.Query(sqlListTags)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tags := make([]TagCount, 0)
+	for rows.Next() {
+		tc := TagCount{}
+		if err = rows.Scan(&tc.Tag, &tc.Count); err != nil {
+			return nil, err
+		}
+		tags = append(tags, tc)
+	}
+
+	return tags, rows.Err()
+}
+
+// RandomQuoteByTag gets a random quote carrying the given tag.
+func (q *QuoteDB) RandomQuoteByTag(tag string) (id int, quote string, err error) {
+	err = q.db.QueryRow(sqlRandomQuoteByTag, tag).Scan(&id, &quote)
+	return
+}
+
+// SearchByTags returns quotes matching the tag filter: a quote matches
+// if it carries any tag in any (when any is non-empty) and every tag in
+// all (when all is non-empty).
+func (q *QuoteDB) SearchByTags(any, all []string) ([]Quote, error) {
+	return q.searchByTags(context.Background(), "", false, any, all)
+}
+
+// searchByTags is shared by SearchByTags and ScopedQuoteDB.SearchByTags.
+// When filterChannel is true, results are additionally restricted to
+// quotes.channel = channel. The JOIN clauses vary with len(any)/len(all),
+// so unlike most of this package's queries this one is built and run
+// fresh each call rather than prepared ahead of time.
+func (q *QuoteDB) searchByTags(ctx context.Context, channel string, filterChannel bool, any, all []string) ([]Quote, error) {
+	query := `SELECT DISTINCT quotes.id, quotes.date, quotes.author, quotes.quote FROM quotes`
+	args := make([]interface{}, 0, len(any)+len(all)+1)
+	var where []string
+
+	if len(any) > 0 {
+		query += ` JOIN quote_tags qt_any ON qt_any.quote_id = quotes.id ` +
+			`JOIN tags t_any ON t_any.id = qt_any.tag_id`
+		placeholders := make([]string, len(any))
+		for i, tag := range any {
+			placeholders[i] = "?"
+			args = append(args, tag)
+		}
+		where = append(where, "t_any.name COLLATE NOCASE IN ("+strings.Join(placeholders, ",")+")")
+	}
+
+	for i, tag := range all {
+		alias := fmt.Sprintf("qt_all%d", i)
+		talias := fmt.Sprintf("t_all%d", i)
+		query += fmt.Sprintf(
+			` JOIN quote_tags %s ON %s.quote_id = quotes.id JOIN tags %s ON %s.id = %s.tag_id`,
+			alias, alias, talias, talias, alias,
+		)
+		where = append(where, fmt.Sprintf("%s.name = ? COLLATE NOCASE", talias))
+		args = append(args, tag)
+	}
+
+	if filterChannel {
+		where = append(where, "quotes.channel = ?")
+		args = append(args, channel)
+	}
+
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	query += " ORDER BY quotes.id DESC;"
+
+	rows, err := q.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	quotes := make([]Quote, 0)
+	for rows.Next() {
+		var date int64
+		quote := Quote{}
+		if err = rows.Scan(&quote.ID, &date, &quote.Author, &quote.Quote); err != nil {
+			return nil, err
+		}
+		quote.Date = time.Unix(date, 0).UTC()
+		quotes = append(quotes, quote)
+	}
+
+	return quotes, rows.Err()
+}